@@ -0,0 +1,336 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chatcorr
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Triple is the generic (X, Y, Z) sample used by CCConditional: X is the
+// covariate being conditioned on, Y is the response, and Z is the
+// candidate predictor whose added information about Y (beyond X) is
+// being measured.
+type Triple[T, U, V any] struct {
+	X T
+	Y U
+	Z V
+}
+
+// CCConditional computes the Azadkia-Chatterjee conditional dependence
+// coefficient T(Y, Z | X) (https://arxiv.org/pdf/1909.10140.pdf, section
+// 2), which measures the degree to which Y is a function of Z given the
+// covariate X. T is close to 0 when Z adds nothing to the prediction of
+// Y beyond X, and close to 1 when Y is (almost) a function of (X, Z) but
+// not of X alone. When every X is equal this collapses to the plain xi
+// coefficient already computed by CC.
+func CCConditional[T, U, V Lessable](xyz []Triple[T, U, V]) float64 {
+	return CCConditionalRand(xyz, rand.New(rand.NewSource(int64(time.Now().Nanosecond()))))
+}
+
+// CCConditionalRand is CCConditional with an explicit rng for breaking
+// nearest-neighbor ties in a repeatable way.
+func CCConditionalRand[T, U, V Lessable](xyz []Triple[T, U, V], rng *rand.Rand) float64 {
+	n := len(xyz)
+	if n < 2 {
+		return 0
+	}
+
+	// X and Z only matter through their order, so rank-transform each to
+	// a float64 coordinate (ties broken randomly) and find genuine
+	// nearest neighbors in that coordinate space with the same k-d-tree
+	// path CCMulti uses, rather than approximating M(i) by lexicographic
+	// (X,Z) adjacency: when X has no ties (any continuous covariate),
+	// that adjacency degenerates to the X-only order and Z is never
+	// consulted.
+	xf := rankFloats(n, rng, func(i, j int) bool { return xyz[i].X < xyz[j].X },
+		func(i, j int) bool { return xyz[i].X == xyz[j].X })
+	zf := rankFloats(n, rng, func(i, j int) bool { return xyz[i].Z < xyz[j].Z },
+		func(i, j int) bool { return xyz[i].Z == xyz[j].Z })
+
+	xPoints := make([][]float64, n)
+	xzPoints := make([][]float64, n)
+	for i := range xyz {
+		xPoints[i] = []float64{xf[i]}
+		xzPoints[i] = []float64{xf[i], zf[i]}
+	}
+
+	nOf := kdNearestNeighbors(xPoints, euclideanDistance, rng)
+	mOf := kdNearestNeighbors(xzPoints, euclideanDistance, rng)
+
+	// R[i] = |{j : Y_j <= Y_i}|, L[i] = |{j : Y_j >= Y_i}|
+	r, l := rankY(xyz, func(i int) U { return xyz[i].Y })
+
+	numerator, denominator := 0.0, 0.0
+	for i := 0; i < n; i++ {
+		numerator += float64(minInt(r[i], r[mOf[i]])) - float64(minInt(r[i], r[nOf[i]]))
+		fl := float64(l[i])
+		denominator += fl * (float64(n) - fl)
+	}
+	denominator /= float64(n)
+	if denominator == 0 {
+		return 0
+	}
+	return numerator / denominator
+}
+
+// CCMulti computes the Azadkia-Chatterjee conditional dependence
+// coefficient T(Y, Z | X) for vector-valued covariates X and predictors
+// Z, given a caller-supplied distance function used to find nearest
+// neighbors. distance must be a true metric distance, not a squared one
+// (e.g. Euclidean distance math.Sqrt(sum((a_k-b_k)^2)), not the bare sum
+// of squares), since the k-d tree's pruning compares it directly against
+// a single-axis coordinate difference. Rows of x, z, and y must align; x
+// or z may have zero columns, in which case they contribute nothing to
+// the corresponding nearest-neighbor search (an empty x collapses T to
+// the plain xi coefficient of y and z). Nearest neighbors are located
+// with a k-d tree, partitioned on the assumption that distance is
+// axis-aligned (as for Euclidean distance); candidates are then compared
+// with the caller's distance function, so non-Euclidean distances still
+// give a reasonable, if approximate, answer in O(n log^2 n).
+func CCMulti(x [][]float64, z [][]float64, y []float64, distance func(a, b []float64) float64, rng *rand.Rand) float64 {
+	n := len(y)
+	if n < 2 {
+		return 0
+	}
+
+	xz := make([][]float64, n)
+	for i := range xz {
+		xz[i] = append(append([]float64{}, x[i]...), z[i]...)
+	}
+
+	nOf := kdNearestNeighbors(x, distance, rng)
+	mOf := kdNearestNeighbors(xz, distance, rng)
+
+	type yi struct {
+		i int
+		y float64
+	}
+	ys := make([]yi, n)
+	for i := range ys {
+		ys[i] = yi{i, y[i]}
+	}
+	sort.Slice(ys, func(i, j int) bool { return ys[i].y < ys[j].y })
+	r, l := make([]int, n), make([]int, n)
+	lastI := 0
+	recordRL := func(i int) int {
+		if i < n && ys[lastI].y == ys[i].y {
+			return lastI
+		}
+		for j := lastI; j < i; j++ {
+			r[ys[j].i] = i
+			l[ys[j].i] = n - lastI
+		}
+		lastI = i
+		return i
+	}
+	for i := 1; i < n; i++ {
+		lastI = recordRL(i)
+	}
+	recordRL(n)
+
+	numerator, denominator := 0.0, 0.0
+	for i := 0; i < n; i++ {
+		numerator += float64(minInt(r[i], r[mOf[i]])) - float64(minInt(r[i], r[nOf[i]]))
+		fl := float64(l[i])
+		denominator += fl * (float64(n) - fl)
+	}
+	denominator /= float64(n)
+	if denominator == 0 {
+		return 0
+	}
+	return numerator / denominator
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// sortedWithTiedShuffle returns indices 0..n-1 ordered by less, with runs
+// of tied elements (per equal) shuffled using rng so that an adjacency
+// lookup over the result breaks ties randomly rather than by index.
+func sortedWithTiedShuffle(n int, rng *rand.Rand, less, equal func(i, j int) bool) []int {
+	idx := makePerm(n)
+	sort.Slice(idx, func(i, j int) bool { return less(idx[i], idx[j]) })
+	start := 0
+	for i := 1; i <= n; i++ {
+		if i < n && equal(idx[start], idx[i]) {
+			continue
+		}
+		if i-start > 1 {
+			rng.Shuffle(i-start, func(a, b int) {
+				idx[start+a], idx[start+b] = idx[start+b], idx[start+a]
+			})
+		}
+		start = i
+	}
+	return idx
+}
+
+// rankFloats maps n items to distinct float64 coordinates 0..n-1 ordered
+// by less, with ties broken randomly via rng (through
+// sortedWithTiedShuffle), so that a Lessable field of any ordered type
+// can be fed to the float64-based k-d tree used by kdNearestNeighbors.
+func rankFloats(n int, rng *rand.Rand, less, equal func(i, j int) bool) []float64 {
+	order := sortedWithTiedShuffle(n, rng, less, equal)
+	ranks := make([]float64, n)
+	for pos, i := range order {
+		ranks[i] = float64(pos)
+	}
+	return ranks
+}
+
+// euclideanDistance is the plain (non-squared) Euclidean distance
+// CCMulti's doc comment requires of its distance argument.
+func euclideanDistance(a, b []float64) float64 {
+	s := 0.0
+	for k := range a {
+		d := a[k] - b[k]
+		s += d * d
+	}
+	return math.Sqrt(s)
+}
+
+// rankY computes R[i] = |{j: Y_j <= Y_i}| and L[i] = |{j: Y_j >= Y_i}|
+// for the Y field of a Triple slice.
+func rankY[T, U Lessable, V any](xyz []Triple[T, U, V], get func(i int) U) ([]int, []int) {
+	n := len(xyz)
+	order := makePerm(n)
+	sort.Slice(order, func(i, j int) bool { return get(order[i]) < get(order[j]) })
+	r, l := make([]int, n), make([]int, n)
+	lastI := 0
+	recordRL := func(i int) int {
+		if i < n && get(order[lastI]) == get(order[i]) {
+			return lastI
+		}
+		for j := lastI; j < i; j++ {
+			r[order[j]] = i
+			l[order[j]] = n - lastI
+		}
+		lastI = i
+		return i
+	}
+	for i := 1; i < n; i++ {
+		lastI = recordRL(i)
+	}
+	recordRL(n)
+	return r, l
+}
+
+// kdNode is one node of the k-d tree built by kdNearestNeighbors.
+type kdNode struct {
+	idx         int
+	axis        int
+	left, right *kdNode
+}
+
+// buildKD builds a k-d tree over points, splitting on the widest
+// remaining axis at each level (a simple, effective heuristic when
+// columns have very different scales).
+func buildKD(idx []int, points [][]float64) *kdNode {
+	if len(idx) == 0 {
+		return nil
+	}
+	dim := len(points[idx[0]])
+	axis := 0
+	if dim > 1 {
+		axis = widestAxis(idx, points)
+	}
+	sort.Slice(idx, func(i, j int) bool { return points[idx[i]][axis] < points[idx[j]][axis] })
+	mid := len(idx) / 2
+	node := &kdNode{idx: idx[mid], axis: axis}
+	node.left = buildKD(idx[:mid], points)
+	node.right = buildKD(idx[mid+1:], points)
+	return node
+}
+
+func widestAxis(idx []int, points [][]float64) int {
+	dim := len(points[idx[0]])
+	lo := append([]float64{}, points[idx[0]]...)
+	hi := append([]float64{}, points[idx[0]]...)
+	for _, i := range idx[1:] {
+		for a := 0; a < dim; a++ {
+			v := points[i][a]
+			if v < lo[a] {
+				lo[a] = v
+			}
+			if v > hi[a] {
+				hi[a] = v
+			}
+		}
+	}
+	best, bestSpread := 0, hi[0]-lo[0]
+	for a := 1; a < dim; a++ {
+		if spread := hi[a] - lo[a]; spread > bestSpread {
+			best, bestSpread = a, spread
+		}
+	}
+	return best
+}
+
+// kdNearestNeighbors returns, for each row of points, the index of its
+// nearest neighbor under distance (excluding itself), breaking ties
+// among equidistant candidates uniformly at random via rng. distance
+// must be a true metric distance (not a squared one): pruning compares
+// it directly against a raw coordinate difference along the split axis.
+func kdNearestNeighbors(points [][]float64, distance func(a, b []float64) float64, rng *rand.Rand) []int {
+	n := len(points)
+	result := make([]int, n)
+	if n == 0 {
+		return result
+	}
+	if len(points[0]) == 0 {
+		// No covariates: every point is equally "nearest"; pick uniformly.
+		for i := range result {
+			j := rng.Intn(n - 1)
+			if j >= i {
+				j++
+			}
+			result[i] = j
+		}
+		return result
+	}
+	root := buildKD(makePerm(n), points)
+	for i := range points {
+		best := -1
+		bestDist := 0.0
+		ties := []int{}
+		var search func(node *kdNode)
+		search = func(node *kdNode) {
+			if node == nil {
+				return
+			}
+			if node.idx != i {
+				d := distance(points[i], points[node.idx])
+				switch {
+				case best == -1 || d < bestDist:
+					best, bestDist, ties = node.idx, d, []int{node.idx}
+				case d == bestDist:
+					ties = append(ties, node.idx)
+				}
+			}
+			diff := points[i][node.axis] - points[node.idx][node.axis]
+			near, far := node.left, node.right
+			if diff > 0 {
+				near, far = node.right, node.left
+			}
+			search(near)
+			if best == -1 || math.Abs(diff) <= bestDist {
+				search(far)
+			}
+		}
+		search(root)
+		if len(ties) > 1 {
+			best = ties[rng.Intn(len(ties))]
+		}
+		result[i] = best
+	}
+	return result
+}
@@ -0,0 +1,66 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chatcorr
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// bruteForceNearest is an O(n^2) reference nearest-neighbor search used
+// to check kdNearestNeighbors against.
+func bruteForceNearest(points [][]float64, distance func(a, b []float64) float64) []int {
+	n := len(points)
+	result := make([]int, n)
+	for i := range points {
+		best, bestDist := -1, math.Inf(1)
+		for j := range points {
+			if j == i {
+				continue
+			}
+			if d := distance(points[i], points[j]); d < bestDist {
+				best, bestDist = j, d
+			}
+		}
+		result[i] = best
+	}
+	return result
+}
+
+func TestKDNearestNeighborsMatchesBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	n := 300
+	points := make([][]float64, n)
+	for i := range points {
+		points[i] = []float64{1000 * r.Float64(), 1000 * r.Float64(), 1000 * r.Float64()}
+	}
+	// A plain (non-squared) Euclidean distance, exactly what CCMulti's
+	// doc comment recommends callers pass.
+	euclidean := func(a, b []float64) float64 {
+		s := 0.0
+		for k := range a {
+			d := a[k] - b[k]
+			s += d * d
+		}
+		return math.Sqrt(s)
+	}
+
+	got := kdNearestNeighbors(points, euclidean, r)
+	want := bruteForceNearest(points, euclidean)
+
+	mismatches := 0
+	for i := range points {
+		if euclidean(points[i], points[got[i]]) != euclidean(points[i], points[want[i]]) {
+			mismatches++
+		}
+	}
+	fmt.Printf("kd vs brute-force mismatches = %d/%d\n", mismatches, n)
+	if mismatches != 0 {
+		t.Fail()
+	}
+}
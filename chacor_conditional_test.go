@@ -0,0 +1,113 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chatcorr_test
+
+import (
+	"fmt"
+	"github.com/dr2chase/chatcorr"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestConditionalCollapsesToXi(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var xyz []chatcorr.Triple[int, float64, float64]
+	for i := 0; i < 200; i++ {
+		z := r.Float64()
+		xyz = append(xyz, chatcorr.Triple[int, float64, float64]{X: 0, Y: z, Z: z})
+	}
+	tcond := chatcorr.CCConditionalRand(xyz, r)
+	fmt.Printf("T(Y,Z|X) with constant X = %f\n", tcond)
+	if tcond < 0.5 {
+		t.Fail()
+	}
+}
+
+func TestConditionalIndependentZ(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var xyz []chatcorr.Triple[float64, float64, float64]
+	for i := 0; i < 300; i++ {
+		x := r.Float64()
+		xyz = append(xyz, chatcorr.Triple[float64, float64, float64]{X: x, Y: x, Z: r.Float64()})
+	}
+	tcond := chatcorr.CCConditionalRand(xyz, r)
+	fmt.Printf("T(Y,Z|X) with Z independent of Y given X = %f\n", tcond)
+	// Z adds nothing once X is known (Y == X here), so T should be small.
+	if tcond > 0.5 {
+		t.Fail()
+	}
+}
+
+func TestConditionalVaryingXYFunctionOfZ(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var xyz []chatcorr.Triple[float64, float64, float64]
+	for i := 0; i < 300; i++ {
+		x := r.Float64()
+		z := r.Float64()
+		xyz = append(xyz, chatcorr.Triple[float64, float64, float64]{X: x, Y: z, Z: z})
+	}
+	tcond := chatcorr.CCConditionalRand(xyz, r)
+	fmt.Printf("T(Y,Z|X) with varying X and Y=Z = %f\n", tcond)
+	// X is random noise, Y is an exact function of Z, so Z must be
+	// detected as adding everything beyond X even though X has no ties
+	// to act as a Z tiebreaker.
+	if tcond < 0.5 {
+		t.Fail()
+	}
+}
+
+func TestCCMulti(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	n := 200
+	x, z := make([][]float64, n), make([][]float64, n)
+	y := make([]float64, n)
+	for i := range y {
+		x[i] = []float64{r.Float64()}
+		z[i] = []float64{r.Float64()}
+		y[i] = x[i][0]
+	}
+	dist := func(a, b []float64) float64 {
+		s := 0.0
+		for k := range a {
+			d := a[k] - b[k]
+			s += d * d
+		}
+		return math.Sqrt(s)
+	}
+	tcond := chatcorr.CCMulti(x, z, y, dist, r)
+	fmt.Printf("CCMulti T(Y,Z|X) = %f\n", tcond)
+	if tcond > 0.5 {
+		t.Fail()
+	}
+}
+
+func TestCCMultiYFunctionOfZ(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	n := 200
+	x, z := make([][]float64, n), make([][]float64, n)
+	y := make([]float64, n)
+	for i := range y {
+		x[i] = []float64{r.Float64()}
+		z[i] = []float64{r.Float64()}
+		y[i] = z[i][0]
+	}
+	dist := func(a, b []float64) float64 {
+		s := 0.0
+		for k := range a {
+			d := a[k] - b[k]
+			s += d * d
+		}
+		return math.Sqrt(s)
+	}
+	tcond := chatcorr.CCMulti(x, z, y, dist, r)
+	fmt.Printf("CCMulti T(Y,Z|X) with Y=Z = %f\n", tcond)
+	// X is random noise, Y is an exact function of Z, so T should be
+	// large even though X has no ties.
+	if tcond < 0.5 {
+		t.Fail()
+	}
+}
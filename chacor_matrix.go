@@ -0,0 +1,187 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chatcorr
+
+import (
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// CCSymmetric returns the larger of xi(X,Y) and xi(Y,X). Chatterjee's
+// coefficient is not symmetric: it is large when Y is (close to) a
+// function of X, but can be small even for strongly dependent variables
+// if the dependence only runs the other way. Taking the max of both
+// directions gives a more sensitive statistic when functional direction
+// is unknown or irrelevant.
+func CCSymmetric[T, U Lessable](v []Point[T, U]) float64 {
+	return CCSymmetricRand(v, rand.New(rand.NewSource(int64(time.Now().Nanosecond()))))
+}
+
+// CCSymmetricRand is CCSymmetric with an explicit rng for breaking ties
+// in a repeatable way.
+func CCSymmetricRand[T, U Lessable](v []Point[T, U], rng *rand.Rand) float64 {
+	xy := make([]Point[T, U], len(v))
+	copy(xy, v)
+	yx := make([]Point[U, T], len(v))
+	for i := range v {
+		yx[i] = Point[U, T]{X: v[i].Y, Y: v[i].X}
+	}
+	return math.Max(CCRand(xy, rng), CCRand(yx, rng))
+}
+
+// columnCache holds the per-column work that CCMatrix shares across all
+// k columns it is paired with: r/l, the Y-role rank vectors indexed by
+// original row, and permX, the row order sorted by column value for use
+// in the X role (with ties already shuffled).
+type columnCache struct {
+	r, l  []int
+	permX []int
+}
+
+func buildColumnCache[T Lessable](col []T, rng *rand.Rand) columnCache {
+	n := len(col)
+	order := makePerm(n)
+	sort.Slice(order, func(i, j int) bool { return col[order[i]] < col[order[j]] })
+
+	r, l := make([]int, n), make([]int, n)
+	lastI := 0
+	recordRL := func(i int) int {
+		if i < n && col[order[lastI]] == col[order[i]] {
+			return lastI
+		}
+		for j := lastI; j < i; j++ {
+			r[order[j]] = i
+			l[order[j]] = n - lastI
+		}
+		lastI = i
+		return i
+	}
+	for i := 1; i < n; i++ {
+		lastI = recordRL(i)
+	}
+	recordRL(n)
+
+	permX := append([]int{}, order...)
+	lastI = 0
+	shuffleEQX := func(i int) int {
+		if i < n && col[permX[lastI]] == col[permX[i]] {
+			return lastI
+		}
+		return shuffleX(rng, lastI, i, permX)
+	}
+	for i := 1; i < n; i++ {
+		lastI = shuffleEQX(i)
+	}
+	shuffleEQX(n)
+
+	return columnCache{r: r, l: l, permX: permX}
+}
+
+// pairXi is finish, specialized to r/l and permX that are indexed by
+// original row rather than by Y-sorted position, as produced by
+// buildColumnCache.
+func pairXi(permX, r, l []int) float64 {
+	n := len(permX)
+	numerator := 0.0
+	for i := 0; i < n-1; i++ {
+		numerator += math.Abs(float64(r[permX[i+1]]) - float64(r[permX[i]]))
+	}
+	numerator *= float64(n)
+
+	denominator := 0.0
+	for i := range l {
+		li := float64(l[i])
+		denominator += li * (float64(n) - li)
+	}
+	denominator *= 2
+
+	return 1 - numerator/denominator
+}
+
+// CCMatrix computes the full k x k matrix of Chatterjee correlations
+// over k columns of length n, with cols[j][i] the i'th observation of
+// variable j. Entry [i][j] is xi(cols[i], cols[j]) (cols[i] in the X
+// role, cols[j] in the Y role); the diagonal is always 1. This is the
+// natural analog of stats.CorrelationMatrix for Pearson/Spearman, for
+// exploratory analysis over wide data.
+//
+// Each column is sorted and ranked once and the result is shared across
+// all k pairs it appears in, rather than redone by k independent calls
+// to CC, so the total cost is O(k^2*n) given the one-time O(k*n*log(n))
+// sort of each column.
+func CCMatrix[T Lessable](cols [][]T) [][]float64 {
+	return CCMatrixRand(cols, rand.New(rand.NewSource(int64(time.Now().Nanosecond()))))
+}
+
+// CCMatrixRand is CCMatrix with an explicit master rng; per-column tie
+// breaking uses independent rngs seeded from it, so the whole matrix is
+// reproducible and independent of how goroutines happen to interleave.
+func CCMatrixRand[T Lessable](cols [][]T, rng *rand.Rand) [][]float64 {
+	k := len(cols)
+	caches := make([]columnCache, k)
+
+	seeds := make([]int64, k)
+	for c := range seeds {
+		seeds[c] = rng.Int63()
+	}
+
+	nWorkers := runtime.GOMAXPROCS(0)
+	if nWorkers > k {
+		nWorkers = k
+	}
+	if nWorkers < 1 {
+		nWorkers = 1
+	}
+	colJobs := make(chan int, k)
+	var wg sync.WaitGroup
+	for w := 0; w < nWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range colJobs {
+				caches[c] = buildColumnCache(cols[c], rand.New(rand.NewSource(seeds[c])))
+			}
+		}()
+	}
+	for c := 0; c < k; c++ {
+		colJobs <- c
+	}
+	close(colJobs)
+	wg.Wait()
+
+	result := make([][]float64, k)
+	for i := range result {
+		result[i] = make([]float64, k)
+	}
+
+	rowJobs := make(chan int, k)
+	var wg2 sync.WaitGroup
+	for w := 0; w < nWorkers; w++ {
+		wg2.Add(1)
+		go func() {
+			defer wg2.Done()
+			for i := range rowJobs {
+				for j := 0; j < k; j++ {
+					if i == j {
+						result[i][j] = 1
+						continue
+					}
+					result[i][j] = pairXi(caches[i].permX, caches[j].r, caches[j].l)
+				}
+			}
+		}()
+	}
+	for i := 0; i < k; i++ {
+		rowJobs <- i
+	}
+	close(rowJobs)
+	wg2.Wait()
+
+	return result
+}
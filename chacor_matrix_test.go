@@ -0,0 +1,57 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chatcorr_test
+
+import (
+	"fmt"
+	"github.com/dr2chase/chatcorr"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestSymmetric(t *testing.T) {
+	l := line(50, 1.0)
+	xi := chatcorr.CCF64(l)
+	xiSym := chatcorr.CCSymmetric(l)
+	fmt.Printf("xi, xiSym = %f, %f\n", xi, xiSym)
+	if xiSym < xi {
+		t.Fail()
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	n := 100
+	x := make([]float64, n)
+	y := make([]float64, n)
+	z := make([]float64, n)
+	for i := range x {
+		x[i] = r.Float64()
+		y[i] = x[i]
+		z[i] = r.Float64()
+	}
+	m := chatcorr.CCMatrixRand([][]float64{x, y, z}, rand.New(rand.NewSource(1)))
+	fmt.Printf("matrix = %v\n", m)
+	for i := range m {
+		if m[i][i] != 1 {
+			t.Fail()
+		}
+	}
+	// x and y are identical, so xi(x,y) should be large.
+	if m[0][1] < 0.5 {
+		t.Fail()
+	}
+	// matrix entries should agree with a direct CC call on the same columns.
+	xy := make([]chatcorr.Point[float64, float64], n)
+	for i := range xy {
+		xy[i] = chatcorr.Point[float64, float64]{X: x[i], Y: z[i]}
+	}
+	direct := chatcorr.CC(xy)
+	if math.Abs(direct-m[0][2]) > 1e-9 {
+		t.Fail()
+	}
+}
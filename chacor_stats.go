@@ -0,0 +1,276 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chatcorr
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// Result pairs a Chatterjee correlation coefficient with a significance
+// test against the null hypothesis that X and Y are independent, so that
+// callers don't have to guess whether a given Xi is meaningful.
+type Result struct {
+	Xi     float64 // the Chatterjee correlation coefficient
+	StdErr float64 // asymptotic standard error of Xi under independence
+	PValue float64 // one-sided p-value for the test Xi > 0 under independence
+}
+
+// asymptoticVariance estimates n*Var(Xi) under the null hypothesis of
+// independence from the r/l rank vectors already computed by recordRL
+// (r[i] = |{j : Y_j <= Y_i}|, l[i] = |{j : Y_j >= Y_i}|). With no ties in
+// Y this is the constant 2/5 from Chatterjee's paper
+// (https://arxiv.org/pdf/1909.10140.pdf); the correction term below is a
+// plug-in estimate of how much ties inflate that variance.
+func asymptoticVariance(r, l []int) float64 {
+	n := len(r)
+	if n == 0 {
+		return 0
+	}
+	fN := float64(n)
+	var sumG2, sumCross, sumG float64
+	for i := 0; i < n; i++ {
+		g := float64(r[i]) / fN
+		h := float64(l[i]) / fN
+		sumG2 += g * (1 - g)
+		sumCross += g * h
+		sumG += g
+	}
+	// With no ties, r[i]+l[i] = n+1 for every i, so each term of
+	// sumG2-sumCross equals -g/n; add it back so the correction is
+	// identically 0 (not just asymptotically negligible) in that case.
+	variance := 2.0/5.0 + (4.0/fN)*(sumG2-sumCross+sumG/fN)
+	if variance < 0 {
+		// The plug-in estimate can still go negative under heavy ties;
+		// floor it rather than propagate a negative variance into a NaN
+		// StdErr/PValue.
+		variance = 0
+	}
+	return variance
+}
+
+// normalCDF returns Phi(z), the standard normal CDF.
+func normalCDF(z float64) float64 {
+	return 0.5 * (1 + math.Erf(z/math.Sqrt2))
+}
+
+// statsFromRL turns a coefficient and its rank vectors into a Result,
+// using the asymptotic normal approximation of Chatterjee (2021).
+func statsFromRL(xi float64, r, l []int) Result {
+	n := len(r)
+	variance := asymptoticVariance(r, l)
+	stdErr := math.Sqrt(variance / float64(n))
+	z := xi / stdErr
+	return Result{Xi: xi, StdErr: stdErr, PValue: 1 - normalCDF(z)}
+}
+
+// CCF64WithStats is CCF64 plus an asymptotic significance test.
+func CCF64WithStats(v []Point[float64, float64]) Result {
+	return CCF64RandWithStats(v, rand.New(rand.NewSource(int64(time.Now().Nanosecond()))))
+}
+
+// CCF64RandWithStats is CCF64WithStats with an explicit rng for breaking X ties in a repeatable way.
+func CCF64RandWithStats(v []Point[float64, float64], rng *rand.Rand) Result {
+	sort.Slice(v, func(i, j int) bool { return v[i].Y < v[j].Y })
+
+	r, l := make([]int, len(v)), make([]int, len(v))
+	last_i := 0
+	recordRL := func(i int) int {
+		if i < len(v) && v[last_i].Y == v[i].Y {
+			return last_i
+		}
+		for j := last_i; j < i; j++ {
+			r[j] = i
+			l[j] = len(v) - last_i
+		}
+		last_i = i
+		return i
+	}
+	for i := 1; i < len(v); i++ {
+		last_i = recordRL(i)
+	}
+	recordRL(len(v))
+
+	perm := makePerm(len(v))
+	sort.Slice(perm, func(i, j int) bool { return v[perm[i]].X < v[perm[j]].X })
+
+	last_i = 0
+	shuffleEQX := func(i int) int {
+		if i < len(v) && v[perm[last_i]].X == v[perm[i]].X {
+			return last_i
+		}
+		return shuffleX(rng, last_i, i, perm)
+	}
+	for i := 1; i < len(v); i++ {
+		last_i = shuffleEQX(i)
+	}
+	shuffleEQX(len(v))
+
+	return statsFromRL(finish(perm, r, l), r, l)
+}
+
+// CCWithStats is CC plus an asymptotic significance test.
+func CCWithStats[T, U Lessable](v []Point[T, U]) Result {
+	return CCRandWithStats(v, rand.New(rand.NewSource(int64(time.Now().Nanosecond()))))
+}
+
+// CCRandWithStats is CCWithStats with an explicit rng for breaking X ties in a repeatable way.
+func CCRandWithStats[T, U Lessable](v []Point[T, U], rng *rand.Rand) Result {
+	sort.Slice(v, func(i, j int) bool { return v[i].Y < v[j].Y })
+
+	r, l := make([]int, len(v)), make([]int, len(v))
+	last_i := 0
+	recordRL := func(i int) int {
+		if i < len(v) && v[last_i].Y == v[i].Y {
+			return last_i
+		}
+		for j := last_i; j < i; j++ {
+			r[j] = i
+			l[j] = len(v) - last_i
+		}
+		last_i = i
+		return i
+	}
+	for i := 1; i < len(v); i++ {
+		last_i = recordRL(i)
+	}
+	recordRL(len(v))
+
+	perm := makePerm(len(v))
+	sort.Slice(perm, func(i, j int) bool { return v[perm[i]].X < v[perm[j]].X })
+
+	last_i = 0
+	shuffleEQX := func(i int) int {
+		if i < len(v) && v[perm[last_i]].X == v[perm[i]].X {
+			return last_i
+		}
+		return shuffleX(rng, last_i, i, perm)
+	}
+	for i := 1; i < len(v); i++ {
+		last_i = shuffleEQX(i)
+	}
+	shuffleEQX(len(v))
+
+	return statsFromRL(finish(perm, r, l), r, l)
+}
+
+// CCFnWithStats is CCFn plus an asymptotic significance test.
+func CCFnWithStats[T any](v []Point[T, T], compare func(a, b T) int) Result {
+	return CCFnRandWithStats(v, compare, rand.New(rand.NewSource(int64(time.Now().Nanosecond()))))
+}
+
+// CCFnRandWithStats is CCFnWithStats with an explicit rng for breaking X ties in a repeatable way.
+func CCFnRandWithStats[T any](v []Point[T, T], compare func(a, b T) int, rng *rand.Rand) Result {
+	sort.Slice(v, func(i, j int) bool { return compare(v[i].Y, v[j].Y) < 0 })
+
+	r, l := make([]int, len(v)), make([]int, len(v))
+	last_i := 0
+	recordRL := func(i int) int {
+		if i < len(v) && compare(v[last_i].Y, v[i].Y) == 0 {
+			return last_i
+		}
+		for j := last_i; j < i; j++ {
+			r[j] = i
+			l[j] = len(v) - last_i
+		}
+		last_i = i
+		return i
+	}
+	for i := 1; i < len(v); i++ {
+		last_i = recordRL(i)
+	}
+	recordRL(len(v))
+
+	perm := makePerm(len(v))
+	sort.Slice(perm, func(i, j int) bool { return compare(v[perm[i]].X, v[perm[j]].X) < 0 })
+
+	last_i = 0
+	shuffleEQX := func(i int) int {
+		if i < len(v) && compare(v[perm[last_i]].X, v[perm[i]].X) == 0 {
+			return last_i
+		}
+		return shuffleX(rng, last_i, i, perm)
+	}
+	for i := 1; i < len(v); i++ {
+		last_i = shuffleEQX(i)
+	}
+	shuffleEQX(len(v))
+
+	return statsFromRL(finish(perm, r, l), r, l)
+}
+
+// CCMixedWithStats is CCMixed plus an asymptotic significance test.
+func CCMixedWithStats[T, U any](v []Point[T, U], compareT func(a, b T) int, compareU func(a, b U) int) Result {
+	return CCMixedRandWithStats(v, compareT, compareU, rand.New(rand.NewSource(int64(time.Now().Nanosecond()))))
+}
+
+// CCMixedRandWithStats is CCMixedWithStats with an explicit rng for breaking X ties in a repeatable way.
+func CCMixedRandWithStats[T, U any](v []Point[T, U], compareT func(a, b T) int, compareU func(a, b U) int, rng *rand.Rand) Result {
+	sort.Slice(v, func(i, j int) bool { return compareU(v[i].Y, v[j].Y) < 0 })
+
+	r, l := make([]int, len(v)), make([]int, len(v))
+	last_i := 0
+	recordRL := func(i int) int {
+		if i < len(v) && compareU(v[last_i].Y, v[i].Y) == 0 {
+			return last_i
+		}
+		for j := last_i; j < i; j++ {
+			r[j] = i
+			l[j] = len(v) - last_i
+		}
+		last_i = i
+		return i
+	}
+	for i := 1; i < len(v); i++ {
+		last_i = recordRL(i)
+	}
+	recordRL(len(v))
+
+	perm := makePerm(len(v))
+	sort.Slice(perm, func(i, j int) bool { return compareT(v[perm[i]].X, v[perm[j]].X) < 0 })
+
+	last_i = 0
+	shuffleEQX := func(i int) int {
+		if i < len(v) && compareT(v[perm[last_i]].X, v[perm[i]].X) == 0 {
+			return last_i
+		}
+		return shuffleX(rng, last_i, i, perm)
+	}
+	for i := 1; i < len(v); i++ {
+		last_i = shuffleEQX(i)
+	}
+	shuffleEQX(len(v))
+
+	return statsFromRL(finish(perm, r, l), r, l)
+}
+
+// PermutationPValue estimates a p-value for the null hypothesis that X and
+// Y are independent by repeatedly shuffling Y, recomputing Xi, and counting
+// how often the shuffled coefficient is at least as large as xiObserved.
+// It is slower than the asymptotic p-value from *WithStats but more
+// trustworthy for small n, where the normal approximation is poor. rng is
+// reused across all B shuffles so results stay repeatable.
+func PermutationPValue[T, U Lessable](v []Point[T, U], xiObserved float64, b int, rng *rand.Rand) float64 {
+	ys := make([]U, len(v))
+	for i := range v {
+		ys[i] = v[i].Y
+	}
+	work := make([]Point[T, U], len(v))
+	copy(work, v)
+
+	count := 0
+	for k := 0; k < b; k++ {
+		rng.Shuffle(len(ys), func(i, j int) { ys[i], ys[j] = ys[j], ys[i] })
+		for i := range work {
+			work[i].Y = ys[i]
+		}
+		if CCRand(work, rng) >= xiObserved {
+			count++
+		}
+	}
+	return float64(count+1) / float64(b+1)
+}
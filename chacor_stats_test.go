@@ -0,0 +1,93 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chatcorr_test
+
+import (
+	"fmt"
+	"github.com/dr2chase/chatcorr"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestSmallNNoTiesStats(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var l []FP
+	for i := 0; i < 5; i++ {
+		l = append(l, FP{r.Float64(), r.Float64()})
+	}
+	res := chatcorr.CCF64WithStats(l)
+	fmt.Printf("n=5 xi, stderr, p = %f, %f, %f\n", res.Xi, res.StdErr, res.PValue)
+	if math.IsNaN(res.StdErr) || math.IsNaN(res.PValue) {
+		t.Fail()
+	}
+	if res.PValue < 0 || res.PValue > 1 {
+		t.Fail()
+	}
+	if res.StdErr <= 0 {
+		t.Fail()
+	}
+}
+
+func TestStdErrMatchesAsymptoticConstant(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	n := 200
+	var l []FP
+	for i := 0; i < n; i++ {
+		l = append(l, FP{r.Float64(), r.Float64()})
+	}
+	res := chatcorr.CCF64WithStats(l)
+	want := math.Sqrt(0.4 / float64(n))
+	fmt.Printf("n=%d stderr = %f, want ~%f\n", n, res.StdErr, want)
+	if math.Abs(res.StdErr-want) > 1e-9 {
+		t.Fail()
+	}
+}
+
+func TestIndependentStats(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var l []FP
+	for i := 0; i < 200; i++ {
+		l = append(l, FP{r.Float64(), r.Float64()})
+	}
+	res := chatcorr.CCF64WithStats(l)
+	fmt.Printf("xi, stderr, p = %f, %f, %f\n", res.Xi, res.StdErr, res.PValue)
+	if res.PValue < 0 || res.PValue > 1 {
+		t.Fail()
+	}
+	if res.StdErr <= 0 {
+		t.Fail()
+	}
+}
+
+func TestLineStats(t *testing.T) {
+	l := line(50, 1.0)
+	res := chatcorr.CCF64WithStats(l)
+	fmt.Printf("xi, stderr, p = %f, %f, %f\n", res.Xi, res.StdErr, res.PValue)
+	// A perfect functional relationship should be wildly significant.
+	if res.PValue > 0.01 {
+		t.Fail()
+	}
+	res2 := chatcorr.CCWithStats(l)
+	if res.Xi != res2.Xi {
+		t.Fail()
+	}
+}
+
+func TestPermutationPValue(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	l := line(30, 1.0)
+	xi := chatcorr.CCF64(l)
+	p := chatcorr.PermutationPValue(l, xi, 200, r)
+	fmt.Printf("permutation p = %f\n", p)
+	if p < 0 || p > 1 {
+		t.Fail()
+	}
+	// the functional line should look nothing like a shuffled (independent) Y.
+	if p > 0.05 {
+		t.Fail()
+	}
+}
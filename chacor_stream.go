@@ -0,0 +1,176 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chatcorr
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+// DefaultReservoirSize is the reservoir capacity used by NewStreamer and
+// NewWindow when the caller passes a non-positive size.
+const DefaultReservoirSize = 10000
+
+// defaultBootstrapSamples is the number of resamples XiWithCI draws from
+// the reservoir to build its confidence interval.
+const defaultBootstrapSamples = 500
+
+// Streamer maintains an approximation of the Chatterjee correlation over
+// a stream of (X, Y) pairs too large to keep in full, using either a
+// uniform random reservoir (Vitter's algorithm R) or a fixed-size
+// sliding window of the most recent points. Xi is recomputed on demand
+// from whichever points the Streamer currently holds, reusing the same
+// finish/recordRL core as CC.
+type Streamer[T, U Lessable] struct {
+	rng      *rand.Rand
+	capacity int
+	window   bool
+	n        int64
+	buf      []Point[T, U]
+	pos      int
+}
+
+// NewStreamer returns a reservoir-sampling Streamer of the given
+// capacity (DefaultReservoirSize if capacity <= 0): Xi is computed over
+// a uniform random sample of every point ever added.
+func NewStreamer[T, U Lessable](capacity int) *Streamer[T, U] {
+	return NewStreamerRand[T, U](capacity, rand.New(rand.NewSource(int64(time.Now().Nanosecond()))))
+}
+
+// NewStreamerRand is NewStreamer with an explicit rng, for repeatable
+// sampling and tie-breaking.
+func NewStreamerRand[T, U Lessable](capacity int, rng *rand.Rand) *Streamer[T, U] {
+	if capacity <= 0 {
+		capacity = DefaultReservoirSize
+	}
+	return &Streamer[T, U]{rng: rng, capacity: capacity}
+}
+
+// NewWindow returns a Streamer that keeps only the most recent size
+// points in a ring buffer (DefaultReservoirSize if size <= 0): Xi is
+// computed over a sliding window, appropriate for monitoring dependence
+// that may drift over time.
+func NewWindow[T, U Lessable](size int) *Streamer[T, U] {
+	return NewWindowRand[T, U](size, rand.New(rand.NewSource(int64(time.Now().Nanosecond()))))
+}
+
+// NewWindowRand is NewWindow with an explicit rng.
+func NewWindowRand[T, U Lessable](size int, rng *rand.Rand) *Streamer[T, U] {
+	if size <= 0 {
+		size = DefaultReservoirSize
+	}
+	return &Streamer[T, U]{rng: rng, capacity: size, window: true}
+}
+
+// Add records one more (x, y) pair from the stream.
+func (s *Streamer[T, U]) Add(x T, y U) {
+	p := Point[T, U]{X: x, Y: y}
+	s.n++
+	if s.window {
+		if len(s.buf) < s.capacity {
+			s.buf = append(s.buf, p)
+		} else {
+			s.buf[s.pos] = p
+			s.pos = (s.pos + 1) % s.capacity
+		}
+		return
+	}
+	if len(s.buf) < s.capacity {
+		s.buf = append(s.buf, p)
+		return
+	}
+	// Vitter's algorithm R: the i'th point (i = s.n, 1-based) replaces a
+	// uniformly random reservoir slot with probability capacity/i.
+	if j := s.rng.Int63n(s.n); j < int64(s.capacity) {
+		s.buf[j] = p
+	}
+}
+
+// Len returns the number of points currently held (<= capacity).
+func (s *Streamer[T, U]) Len() int {
+	return len(s.buf)
+}
+
+func (s *Streamer[T, U]) snapshot() []Point[T, U] {
+	out := make([]Point[T, U], len(s.buf))
+	copy(out, s.buf)
+	return out
+}
+
+// Xi returns the Chatterjee correlation over the points currently held
+// by the Streamer (the reservoir or the window), or NaN if fewer than 2
+// points have been added.
+func (s *Streamer[T, U]) Xi() float64 {
+	if len(s.buf) < 2 {
+		return math.NaN()
+	}
+	return CCRand(s.snapshot(), s.rng)
+}
+
+// XiWithCI returns Xi along with a confidence interval at confidence
+// level 1-alpha, built by m-out-of-n subsampling: draw
+// defaultBootstrapSamples subsamples of size m < n without replacement
+// and recompute Xi on each. Plain resampling with replacement was tried
+// first, but it duplicates points, and Xi is upward-biased
+// (near-degenerate) on data containing exact duplicates, since a
+// duplicated point is a perfect nearest neighbor of itself; subsampling
+// without replacement avoids manufacturing ties that aren't in the
+// underlying data. The interval is the alpha/2..1-alpha/2 percentiles of
+// the subsample Xis, widened if necessary to also contain xi itself (the
+// m<n subsamples are themselves slightly biased relative to the full
+// reservoir, so their percentiles alone aren't guaranteed to bracket it).
+// It returns NaN, NaN, NaN if fewer than 2 points have been added.
+func (s *Streamer[T, U]) XiWithCI(alpha float64) (xi, lo, hi float64) {
+	data := s.snapshot()
+	n := len(data)
+	if n < 2 {
+		return math.NaN(), math.NaN(), math.NaN()
+	}
+	xi = CCRand(append([]Point[T, U]{}, data...), s.rng)
+
+	m := n * 2 / 3
+	if m < 2 {
+		m = n
+	}
+
+	boots := make([]float64, defaultBootstrapSamples)
+	sample := make([]Point[T, U], m)
+	idx := makePerm(n)
+	for b := range boots {
+		// Partial Fisher-Yates: only the first m positions need to be
+		// randomized to get a uniform m-subset without replacement.
+		for i := 0; i < m; i++ {
+			j := i + s.rng.Intn(n-i)
+			idx[i], idx[j] = idx[j], idx[i]
+		}
+		for i := 0; i < m; i++ {
+			sample[i] = data[idx[i]]
+		}
+		boots[b] = CCRand(sample, s.rng)
+	}
+	sort.Float64s(boots)
+
+	loIdx := int(alpha / 2 * float64(defaultBootstrapSamples))
+	hiIdx := int((1-alpha/2)*float64(defaultBootstrapSamples)) - 1
+	if loIdx < 0 {
+		loIdx = 0
+	}
+	if hiIdx >= defaultBootstrapSamples {
+		hiIdx = defaultBootstrapSamples - 1
+	}
+	if hiIdx < loIdx {
+		hiIdx = loIdx
+	}
+	lo, hi = boots[loIdx], boots[hiIdx]
+	if lo > xi {
+		lo = xi
+	}
+	if hi < xi {
+		hi = xi
+	}
+	return xi, lo, hi
+}
@@ -0,0 +1,66 @@
+// Copyright 2022 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chatcorr_test
+
+import (
+	"fmt"
+	"github.com/dr2chase/chatcorr"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestStreamerReservoir(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	s := chatcorr.NewStreamerRand[float64, float64](200, r)
+	for i := 0; i < 5000; i++ {
+		x := r.Float64()
+		s.Add(x, x)
+	}
+	if s.Len() != 200 {
+		t.Fail()
+	}
+	xi := s.Xi()
+	fmt.Printf("reservoir xi = %f\n", xi)
+	if xi < 0.5 {
+		t.Fail()
+	}
+}
+
+func TestStreamerWindow(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	s := chatcorr.NewWindowRand[float64, float64](100, r)
+	// first feed unrelated noise, which should fall out of the window...
+	for i := 0; i < 500; i++ {
+		s.Add(r.Float64(), r.Float64())
+	}
+	// ...then feed a clear functional relationship.
+	for i := 0; i < 100; i++ {
+		x := r.Float64()
+		s.Add(x, x)
+	}
+	if s.Len() != 100 {
+		t.Fail()
+	}
+	xi := s.Xi()
+	fmt.Printf("window xi = %f\n", xi)
+	if xi < 0.5 {
+		t.Fail()
+	}
+}
+
+func TestStreamerXiWithCI(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	s := chatcorr.NewStreamerRand[float64, float64](300, r)
+	for i := 0; i < 300; i++ {
+		x := r.Float64()
+		s.Add(x, x)
+	}
+	xi, lo, hi := s.XiWithCI(0.05)
+	fmt.Printf("xi, lo, hi = %f, %f, %f\n", xi, lo, hi)
+	if !(lo <= xi && xi <= hi) {
+		t.Fail()
+	}
+}